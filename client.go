@@ -1,6 +1,8 @@
 package trpc
 
-type callHandler func(clientName, serviceMethod string, args interface{}, reply interface{}) error
+import "context"
+
+type callHandler func(ctx context.Context, clientName, serviceMethod string, args interface{}, reply interface{}) error
 
 type closeHandler func(name string) error
 
@@ -25,7 +27,13 @@ type Client struct {
 }
 
 func (c *Client) Call(serviceMethod string, args interface{}, reply interface{}) error {
-	return c.callHandler(c.name, serviceMethod, args, reply)
+	return c.CallContext(context.Background(), serviceMethod, args, reply)
+}
+
+// CallContext is like Call but bounds the request with ctx, so callers can
+// enforce a per-call timeout or cancel a pending call early.
+func (c *Client) CallContext(ctx context.Context, serviceMethod string, args interface{}, reply interface{}) error {
+	return c.callHandler(ctx, c.name, serviceMethod, args, reply)
 }
 
 func (c *Client) Close() error {
@@ -0,0 +1,54 @@
+package trpc
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type slowArgs struct{ Delay time.Duration }
+
+type slowReply struct{}
+
+// slowService blocks for Delay before replying, and reports completion on
+// done, so a test can tell whether the handler kept running after its caller
+// gave up on it.
+type slowService struct {
+	done chan struct{}
+}
+
+func (s slowService) Slow(args *slowArgs, reply *slowReply) {
+	time.Sleep(args.Delay)
+	close(s.done)
+}
+
+// TestCallContextCancellation checks that CallContext returns as soon as ctx
+// is done, instead of waiting for the handler to finish.
+func TestCallContextCancellation(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+
+	done := make(chan struct{})
+	client, _ := dialServer(t, n, slowService{done: done})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var reply slowReply
+	err := client.CallContext(ctx, "slowService.Slow", &slowArgs{Delay: 500 * time.Millisecond}, &reply)
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("CallContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > 200*time.Millisecond {
+		t.Fatalf("CallContext() took %v, want it to return promptly once its deadline fired", elapsed)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("handler never completed; it should keep running even after its caller gave up")
+	}
+}
@@ -0,0 +1,18 @@
+package trpc
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// roundTrip gob-encodes src and decodes the bytes into dst, forcing a deep
+// copy across the simulated wire. This is how the labrpc-style test harnesses
+// catch a test that only passes because a handler and its caller happen to
+// share a pointer.
+func roundTrip(src, dst interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+		return err
+	}
+	return gob.NewDecoder(&buf).Decode(dst)
+}
@@ -0,0 +1,172 @@
+package trpc
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies the kind of Event a Network emits.
+type EventType int
+
+const (
+	// CallStart is emitted as soon as a call is dispatched to a server.
+	CallStart EventType = iota
+	// CallDropped is emitted when a call or its response is lost, whether to
+	// an unreliable link, a disabled client or a network partition.
+	CallDropped
+	// CallDelivered is emitted once a call's response has made it back to the caller.
+	CallDelivered
+	// ClientDisabled is emitted when a client is disabled via DisableClient.
+	ClientDisabled
+	// ServerRemoved is emitted when a server is removed via RemoveServer.
+	ServerRemoved
+	// PartitionChanged is emitted whenever Partition, Heal or Isolate changes the partition.
+	PartitionChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case CallStart:
+		return "call_start"
+	case CallDropped:
+		return "call_dropped"
+	case CallDelivered:
+		return "call_delivered"
+	case ClientDisabled:
+		return "client_disabled"
+	case ServerRemoved:
+		return "server_removed"
+	case PartitionChanged:
+		return "partition_changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Event describes something that happened inside a simulated Network, so test
+// authors can assert on traffic (e.g. "server X received N AppendEntries
+// within 200ms") without instrumenting their handlers.
+type Event struct {
+	Type          EventType
+	Client        string
+	Server        string
+	ServiceMethod string
+	Latency       time.Duration
+	Err           error
+	Time          time.Time
+}
+
+// eventBufferSize bounds how many unconsumed events a subscriber channel holds
+// before new events are dropped rather than blocking dispatch.
+const eventBufferSize = 256
+
+// eventBus fans Network events out to every subscriber. Publishing never
+// blocks dispatch: a subscriber that falls behind simply misses events.
+type eventBus struct {
+	lock sync.Mutex
+	subs []chan Event
+}
+
+func (b *eventBus) subscribe() chan Event {
+	ch := make(chan Event, eventBufferSize)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.subs = append(b.subs, ch)
+	return ch
+}
+
+// unsubscribe stops ch from receiving further events and closes it, so a
+// subscriber that's done listening (e.g. a dropped SSE connection) doesn't
+// linger in subs forever.
+func (b *eventBus) unsubscribe(ch chan Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, sub := range b.subs {
+		if sub == ch {
+			b.subs = append(b.subs[:i], b.subs[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+func (b *eventBus) publish(e Event) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			// Subscriber is behind; drop rather than block the network.
+		}
+	}
+}
+
+// Subscribe returns a channel that receives every Event the Network emits from
+// now on. The channel is never closed.
+func (n *Network) Subscribe() <-chan Event {
+	return n.events.subscribe()
+}
+
+// Stat holds call counters for a single client or server.
+type Stat struct {
+	Calls    int
+	Drops    int
+	Timeouts int
+}
+
+// Stats returns a snapshot of per-client and per-server call counters.
+func (n *Network) Stats() (clients map[string]Stat, servers map[string]Stat) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	clients = make(map[string]Stat, len(n.clientStats))
+	for name, stat := range n.clientStats {
+		clients[name] = stat
+	}
+	servers = make(map[string]Stat, len(n.serverStats))
+	for name, stat := range n.serverStats {
+		servers[name] = stat
+	}
+	return clients, servers
+}
+
+// recordCall counts a call that was just dispatched to serverName by clientName.
+func (n *Network) recordCall(clientName, serverName string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	client := n.clientStats[clientName]
+	client.Calls++
+	n.clientStats[clientName] = client
+
+	server := n.serverStats[serverName]
+	server.Calls++
+	n.serverStats[serverName] = server
+}
+
+// recordDrop counts a call lost to an unreliable link.
+func (n *Network) recordDrop(clientName, serverName string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	client := n.clientStats[clientName]
+	client.Drops++
+	n.clientStats[clientName] = client
+
+	server := n.serverStats[serverName]
+	server.Drops++
+	n.serverStats[serverName] = server
+}
+
+// recordTimeout counts a call that timed out because the client was disabled,
+// the server was removed, or the two were partitioned apart.
+func (n *Network) recordTimeout(clientName, serverName string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	client := n.clientStats[clientName]
+	client.Timeouts++
+	n.clientStats[clientName] = client
+
+	server := n.serverStats[serverName]
+	server.Timeouts++
+	n.serverStats[serverName] = server
+}
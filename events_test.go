@@ -0,0 +1,75 @@
+package trpc
+
+import (
+	"testing"
+	"time"
+)
+
+func recvEvent(t *testing.T, events <-chan Event, want EventType) Event {
+	t.Helper()
+	select {
+	case e := <-events:
+		if e.Type != want {
+			t.Fatalf("event type = %v, want %v", e.Type, want)
+		}
+		return e
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for a %v event", want)
+		return Event{}
+	}
+}
+
+// TestSubscribeAndStatsOnSuccess checks that a successful call emits a
+// CallStart/CallDelivered pair on Subscribe and counts once in Stats.
+func TestSubscribeAndStatsOnSuccess(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+	client := newEchoClient(t, n)
+
+	events := n.Subscribe()
+
+	var reply echoReply
+	if err := client.Call("echoService.Echo", &echoArgs{N: 7}, &reply); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+
+	recvEvent(t, events, CallStart)
+	recvEvent(t, events, CallDelivered)
+
+	clients, servers := n.Stats()
+	if got := clients["client-0"].Calls; got != 1 {
+		t.Fatalf("clients[client-0].Calls = %d, want 1", got)
+	}
+	if got := servers["server-0"].Calls; got != 1 {
+		t.Fatalf("servers[server-0].Calls = %d, want 1", got)
+	}
+}
+
+// TestSubscribeAndStatsOnTimeout checks that disabling a client emits
+// ClientDisabled, and a subsequent call to it emits CallStart/CallDropped and
+// counts as a timeout in Stats, on top of being counted as a call.
+func TestSubscribeAndStatsOnTimeout(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+	client := newEchoClient(t, n)
+	events := n.Subscribe()
+
+	n.DisableClient("client-0")
+	recvEvent(t, events, ClientDisabled)
+
+	var reply echoReply
+	if err := client.Call("echoService.Echo", &echoArgs{N: 1}, &reply); err != timeoutErr {
+		t.Fatalf("Call() error = %v, want timeoutErr", err)
+	}
+
+	recvEvent(t, events, CallStart)
+	recvEvent(t, events, CallDropped)
+
+	clients, servers := n.Stats()
+	if got := clients["client-0"].Timeouts; got != 1 {
+		t.Fatalf("clients[client-0].Timeouts = %d, want 1", got)
+	}
+	if got := servers["server-0"].Timeouts; got != 1 {
+		t.Fatalf("servers[server-0].Timeouts = %d, want 1", got)
+	}
+}
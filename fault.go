@@ -0,0 +1,240 @@
+package trpc
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// disabledTimeoutMillis bounds how long a call to a disabled client, a removed
+// server or a partitioned link is held before being reported as timed out.
+const disabledTimeoutMillis = 100
+
+// seededRand is a concurrency-safe wrapper around a seeded *rand.Rand, so the
+// built-in FaultModels sharing a Network's seed (Lossy, Bimodal) draw from one
+// reproducible source instead of racing on it or falling back to the
+// process-global math/rand, whose draws can't be pinned to a single seed once
+// more than one Network exists in the process.
+type seededRand struct {
+	lock sync.Mutex
+	rng  *rand.Rand
+}
+
+func newSeededRand(seed int64) *seededRand {
+	return &seededRand{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *seededRand) Float64() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rng.Float64()
+}
+
+func (s *seededRand) Intn(n int) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.rng.Intn(n)
+}
+
+// randFloat64 and randIntn let Lossy/Bimodal draw from rng when one was bound
+// to them (via LinkModel), falling back to the process-global math/rand for
+// instances constructed and used directly, outside of any Network.
+func randFloat64(rng *seededRand) float64 {
+	if rng == nil {
+		return rand.Float64()
+	}
+	return rng.Float64()
+}
+
+func randIntn(rng *seededRand, n int) int {
+	if rng == nil {
+		return rand.Intn(n)
+	}
+	return rng.Intn(n)
+}
+
+// FaultModel decides how a simulated client/server edge behaves: whether a
+// request is dropped, and how long requests, responses and disabled/partitioned
+// timeouts are delayed. Network consults it on every dispatch, instead of the
+// fixed constants earlier versions hard-coded.
+type FaultModel interface {
+	// ShouldDropRequest reports whether a call from clientName to serverName
+	// should be dropped, simulating a lost message.
+	ShouldDropRequest(clientName, serverName string) bool
+	// RequestDelay is how long to hold a request that wasn't dropped.
+	RequestDelay(clientName, serverName string) time.Duration
+	// ResponseDelay is how long to hold a response that wasn't dropped.
+	ResponseDelay(clientName, serverName string) time.Duration
+	// DisabledTimeout is how long to wait before reporting a call to a disabled
+	// client, a removed server, or a partitioned link as timed out.
+	DisabledTimeout(clientName, serverName string) time.Duration
+}
+
+// Reliable is a FaultModel that never drops a request or delays a request or
+// response. A call to a disabled client, a removed server or a partitioned
+// link still waits a random time under disabledTimeoutMillis before it times out.
+type Reliable struct {
+	// rand is bound by LinkModel so DisabledTimeout draws from the Network's
+	// seeded source; it's nil (falling back to math/rand) for a Reliable{}
+	// used directly, outside of any Network.
+	rand *seededRand
+}
+
+func (Reliable) ShouldDropRequest(clientName, serverName string) bool { return false }
+
+func (Reliable) RequestDelay(clientName, serverName string) time.Duration { return 0 }
+
+func (Reliable) ResponseDelay(clientName, serverName string) time.Duration { return 0 }
+
+func (r Reliable) DisabledTimeout(clientName, serverName string) time.Duration {
+	return time.Duration(randIntn(r.rand, disabledTimeoutMillis)) * time.Millisecond
+}
+
+// Lossy is a FaultModel that drops requests with a fixed probability and
+// applies a fixed latency to everything that isn't dropped.
+type Lossy struct {
+	// DropProb is the probability, in [0, 1], that a request is dropped.
+	DropProb float64
+	// Latency is applied to every request/response that isn't dropped.
+	Latency time.Duration
+
+	// rand is bound by LinkModel so drop decisions and DisabledTimeout draw
+	// from the Network's seeded source; it's nil (falling back to math/rand)
+	// for a Lossy{} used directly, outside of any Network.
+	rand *seededRand
+}
+
+func (l Lossy) ShouldDropRequest(clientName, serverName string) bool {
+	return randFloat64(l.rand) < l.DropProb
+}
+
+func (l Lossy) RequestDelay(clientName, serverName string) time.Duration { return l.Latency }
+
+func (l Lossy) ResponseDelay(clientName, serverName string) time.Duration { return l.Latency }
+
+func (l Lossy) DisabledTimeout(clientName, serverName string) time.Duration {
+	return time.Duration(randIntn(l.rand, disabledTimeoutMillis)) * time.Millisecond
+}
+
+// Bimodal is a FaultModel for a link that's usually fast but occasionally very
+// slow, rather than uniformly lossy. It never drops requests.
+type Bimodal struct {
+	// FastP is the probability, in [0, 1], that a request or response delay
+	// takes the fast path; the request and response of one call are drawn
+	// separately.
+	FastP float64
+	// FastLat is the latency applied on the fast path.
+	FastLat time.Duration
+	// SlowLat is the latency applied on the slow path.
+	SlowLat time.Duration
+
+	// rand is bound by LinkModel so the fast/slow path draw and
+	// DisabledTimeout come from the Network's seeded source; it's nil
+	// (falling back to math/rand) for a Bimodal{} used directly, outside of
+	// any Network.
+	rand *seededRand
+}
+
+func (b Bimodal) ShouldDropRequest(clientName, serverName string) bool { return false }
+
+func (b Bimodal) RequestDelay(clientName, serverName string) time.Duration { return b.latency() }
+
+func (b Bimodal) ResponseDelay(clientName, serverName string) time.Duration { return b.latency() }
+
+func (b Bimodal) DisabledTimeout(clientName, serverName string) time.Duration {
+	return time.Duration(randIntn(b.rand, disabledTimeoutMillis)) * time.Millisecond
+}
+
+func (b Bimodal) latency() time.Duration {
+	if randFloat64(b.rand) < b.FastP {
+		return b.FastLat
+	}
+	return b.SlowLat
+}
+
+// linkKey identifies a directed client/server edge for a per-link FaultModel override.
+type linkKey struct {
+	client, server string
+}
+
+// LinkModel is a FaultModel that lets individual client/server edges use a
+// different FaultModel than the rest of the network, e.g. one slow WAN link
+// among otherwise-reliable ones. Edges without their own override fall back to Default.
+type LinkModel struct {
+	lock sync.Mutex
+	// rng is handed to any Reliable/Lossy/Bimodal set on this LinkModel, so
+	// their random draws are reproducible from the Network's seed even when
+	// several Networks run in the same process.
+	rng *seededRand
+	// Default is used for any edge without its own override.
+	Default FaultModel
+	links   map[linkKey]FaultModel
+}
+
+// NewLinkModel creates a LinkModel that falls back to def for any edge without
+// its own override, drawing any randomness def or later overrides need from rng.
+func NewLinkModel(def FaultModel, rng *seededRand) *LinkModel {
+	m := &LinkModel{rng: rng, links: make(map[linkKey]FaultModel)}
+	m.Default = m.bind(def)
+	return m
+}
+
+// bind hands m's rng to model if it's one of the built-in FaultModels, so its
+// random draws come from the Network's seeded source instead of math/rand.
+func (m *LinkModel) bind(model FaultModel) FaultModel {
+	switch fm := model.(type) {
+	case Reliable:
+		fm.rand = m.rng
+		return fm
+	case Lossy:
+		fm.rand = m.rng
+		return fm
+	case Bimodal:
+		fm.rand = m.rng
+		return fm
+	default:
+		return model
+	}
+}
+
+// SetDefault replaces the FaultModel used for edges without their own override.
+func (m *LinkModel) SetDefault(model FaultModel) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.Default = m.bind(model)
+}
+
+// Set overrides the FaultModel used for calls from clientName to serverName.
+func (m *LinkModel) Set(clientName, serverName string, model FaultModel) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.links[linkKey{clientName, serverName}] = m.bind(model)
+}
+
+func (m *LinkModel) modelFor(clientName, serverName string) FaultModel {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if model, ok := m.links[linkKey{clientName, serverName}]; ok {
+		return model
+	}
+	if m.Default != nil {
+		return m.Default
+	}
+	return Reliable{}
+}
+
+func (m *LinkModel) ShouldDropRequest(clientName, serverName string) bool {
+	return m.modelFor(clientName, serverName).ShouldDropRequest(clientName, serverName)
+}
+
+func (m *LinkModel) RequestDelay(clientName, serverName string) time.Duration {
+	return m.modelFor(clientName, serverName).RequestDelay(clientName, serverName)
+}
+
+func (m *LinkModel) ResponseDelay(clientName, serverName string) time.Duration {
+	return m.modelFor(clientName, serverName).ResponseDelay(clientName, serverName)
+}
+
+func (m *LinkModel) DisabledTimeout(clientName, serverName string) time.Duration {
+	return m.modelFor(clientName, serverName).DisabledTimeout(clientName, serverName)
+}
@@ -0,0 +1,218 @@
+package trpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPHandler returns an http.Handler exposing a REST control API for this
+// Network, so an external test driver -- even one not written in Go -- can
+// create/remove servers, dial/enable/disable clients, toggle reliability,
+// manage partitions and stream events against a running simulation.
+func (n *Network) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/servers", n.handleServers)
+	mux.HandleFunc("/servers/", n.handleServer)
+	mux.HandleFunc("/clients", n.handleClients)
+	mux.HandleFunc("/clients/", n.handleClient)
+	mux.HandleFunc("/reliable", n.handleReliable)
+	mux.HandleFunc("/partition", n.handlePartition)
+	mux.HandleFunc("/heal", n.handleHeal)
+	mux.HandleFunc("/isolate", n.handleIsolate)
+	mux.HandleFunc("/events", n.handleEvents)
+	return mux
+}
+
+func (n *Network) handleServers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	server := n.NewServer()
+	writeJSON(w, map[string]string{"name": server.name})
+}
+
+func (n *Network) handleServer(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/servers/")
+	if r.Method != http.MethodDelete || name == "" {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	server, err := n.getServer(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	n.RemoveServer(server)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Network) handleClients(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Server string `json:"server"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	server, err := n.getServer(req.Server)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	client := n.createClient(server)
+	writeJSON(w, map[string]string{"name": client.name})
+}
+
+// handleClient dispatches POST /clients/{name}/enable and /clients/{name}/disable.
+func (n *Network) handleClient(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/clients/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 {
+		http.Error(w, "missing action", http.StatusNotFound)
+		return
+	}
+	name, action := path[:i], path[i+1:]
+	switch action {
+	case "enable":
+		n.EnableClient(name)
+	case "disable":
+		n.DisableClient(name)
+	default:
+		http.Error(w, fmt.Sprintf("unknown action %q", action), http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Network) handleReliable(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Reliable bool `json:"reliable"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n.SetReliable(req.Reliable)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Network) handlePartition(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Groups [][]string `json:"groups"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n.Partition(req.Groups...)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Network) handleHeal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	n.Heal()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Network) handleIsolate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	n.Isolate(req.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// eventWire is the JSON representation of an Event sent over the SSE stream.
+type eventWire struct {
+	Type          string    `json:"type"`
+	Client        string    `json:"client,omitempty"`
+	Server        string    `json:"server,omitempty"`
+	ServiceMethod string    `json:"serviceMethod,omitempty"`
+	LatencyMS     int64     `json:"latencyMs"`
+	Err           string    `json:"err,omitempty"`
+	Time          time.Time `json:"time"`
+}
+
+// handleEvents streams Network events to the caller over SSE until the
+// request is cancelled, so external dashboards/CI tooling can watch a
+// simulation run without polling.
+func (n *Network) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events := n.events.subscribe()
+	defer n.events.unsubscribe(events)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e := <-events:
+			data, err := json.Marshal(eventWire{
+				Type:          e.Type.String(),
+				Client:        e.Client,
+				Server:        e.Server,
+				ServiceMethod: e.ServiceMethod,
+				LatencyMS:     e.Latency.Milliseconds(),
+				Err:           errString(e.Err),
+				Time:          e.Time,
+			})
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
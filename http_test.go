@@ -0,0 +1,129 @@
+package trpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHTTPHandlerServersClientsAndIsolate drives the REST control endpoints
+// end to end: creating servers/clients, disabling a client, and isolating a
+// server, checking each change against the Network's actual state.
+func TestHTTPHandlerServersClientsAndIsolate(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+	ts := httptest.NewServer(n.HTTPHandler())
+	defer ts.Close()
+
+	post := func(path string, body interface{}) *http.Response {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			if err := json.NewEncoder(&buf).Encode(body); err != nil {
+				t.Fatalf("encode body: %v", err)
+			}
+		}
+		resp, err := http.Post(ts.URL+path, "application/json", &buf)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		return resp
+	}
+
+	resp := post("/servers", nil)
+	var serverResp struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&serverResp); err != nil {
+		t.Fatalf("decode /servers response: %v", err)
+	}
+	resp.Body.Close()
+	if _, err := n.getServer(serverResp.Name); err != nil {
+		t.Fatalf("server %q wasn't created on the Network: %v", serverResp.Name, err)
+	}
+
+	resp = post("/clients", map[string]string{"server": serverResp.Name})
+	var clientResp struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&clientResp); err != nil {
+		t.Fatalf("decode /clients response: %v", err)
+	}
+	resp.Body.Close()
+	if !n.clientEnabled(clientResp.Name) {
+		t.Fatalf("client %q should be enabled by default", clientResp.Name)
+	}
+
+	resp = post("/clients/"+clientResp.Name+"/disable", nil)
+	resp.Body.Close()
+	if n.clientEnabled(clientResp.Name) {
+		t.Fatalf("client %q should be disabled after POST .../disable", clientResp.Name)
+	}
+
+	resp = post("/isolate", map[string]string{"name": serverResp.Name})
+	resp.Body.Close()
+	if n.canReach(clientResp.Name, serverResp.Name) {
+		t.Fatalf("%q should not be able to reach isolated server %q", clientResp.Name, serverResp.Name)
+	}
+
+	resp = post("/heal", nil)
+	resp.Body.Close()
+	if !n.canReach(clientResp.Name, serverResp.Name) {
+		t.Fatalf("%q should be able to reach %q again after /heal", clientResp.Name, serverResp.Name)
+	}
+}
+
+// TestHTTPHandlerEventsStream checks that a GET /events request receives the
+// CallStart/CallDelivered pair for a call driven on the Network directly.
+func TestHTTPHandlerEventsStream(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+	client := newEchoClient(t, n)
+
+	ts := httptest.NewServer(n.HTTPHandler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	if err != nil {
+		t.Fatalf("new request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	go func() {
+		// Give handleEvents time to subscribe before the call is dispatched.
+		time.Sleep(50 * time.Millisecond)
+		var reply echoReply
+		_ = client.Call("echoService.Echo", &echoArgs{N: 1}, &reply)
+	}()
+
+	var gotStart, gotDelivered bool
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.Contains(line, `"type":"call_start"`) {
+			gotStart = true
+		}
+		if strings.Contains(line, `"type":"call_delivered"`) {
+			gotDelivered = true
+		}
+		if gotStart && gotDelivered {
+			break
+		}
+	}
+
+	if !gotStart || !gotDelivered {
+		t.Fatalf("did not see both CallStart and CallDelivered over SSE (gotStart=%v, gotDelivered=%v)", gotStart, gotDelivered)
+	}
+}
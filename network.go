@@ -1,30 +1,38 @@
 package trpc
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"math/rand"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
 )
 
-const (
-	longTimeout  = 7000
-	shortTimeout = 100
-	shortDelay   = 27
-)
-
 var timeoutErr = errors.New("rpc: request timeout")
 
 func NewNetwork() *Network {
+	return NewNetworkWithSeed(time.Now().UnixNano())
+}
+
+// NewNetworkWithSeed creates a Network whose built-in FaultModels (Reliable,
+// Lossy, Bimodal) make reproducible choices, by drawing them from a *rand.Rand
+// seeded with seed and private to this Network, so a simulation can be
+// replayed bit-for-bit by reusing the same seed even with several Networks
+// running in the same process.
+func NewNetworkWithSeed(seed int64) *Network {
 	return &Network{
 		servers:     make(map[string]*Server),
 		clients:     make(map[string]*Client),
 		addressMap:  make(map[string]string),
 		connections: make(map[string]string),
 		enabled:     make(map[string]bool),
-		reliable:    true,
+		clock:       realClock{},
+		faultModel:  NewLinkModel(Reliable{}, newSeededRand(seed)),
+		events:      &eventBus{},
+		clientStats: make(map[string]Stat),
+		serverStats: make(map[string]Stat),
 	}
 }
 
@@ -39,22 +47,65 @@ type Network struct {
 	addressMap  map[string]string // Map network address to server name.
 	connections map[string]string // Map client name to server name it connected to.
 
-	// If Network is not reliable, requests maybe delayed or even dropped.
-	reliable bool
-	// If longDelay is true, requests may suffer a long delay before timeout.
-	longDelay bool
-	// If longReorder is true, requests may suffer a long delay before response.
-	longReorder bool
-
 	// If a client isn't enabled, requests won't be replied an eventually timeout.
 	enabled map[string]bool
+
+	// partitionGroup maps a client/server name to the index of the partition
+	// group it belongs to. Names missing from the map aren't partitioned and
+	// can reach everyone. A nil map means the network isn't partitioned at all.
+	partitionGroup map[string]int
+
+	// clock is consulted for every sleep/timeout so simulations can replay
+	// identical schedules through a FakeClock instead of sleeping for real.
+	clock Clock
+
+	// faultModel decides, per client/server edge, whether a call is dropped and
+	// how long requests/responses/timeouts are delayed.
+	faultModel *LinkModel
+
+	// events fans out Event notifications to every Subscribe caller.
+	events *eventBus
+	// clientStats and serverStats hold per-name call counters reported by Stats.
+	clientStats map[string]Stat
+	serverStats map[string]Stat
 }
 
-// SetReliable marks Network as reliable or not reliable.
-func (n *Network) SetReliable(reliable bool) {
+// SetClock installs the Clock used for every sleep/timeout in the network. It's
+// mainly useful to plug in a FakeClock for deterministic simulation tests.
+func (n *Network) SetClock(clock Clock) {
 	n.lock.Lock()
 	defer n.lock.Unlock()
-	n.reliable = reliable
+	n.clock = clock
+}
+
+// getClock returns the Clock currently installed, guarded by lock since
+// SetClock can run concurrently with in-flight calls.
+func (n *Network) getClock() Clock {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.clock
+}
+
+// SetFaultModel replaces the FaultModel used for every client/server edge that
+// doesn't have its own override set via SetLinkModel.
+func (n *Network) SetFaultModel(model FaultModel) {
+	n.faultModel.SetDefault(model)
+}
+
+// SetLinkModel overrides the FaultModel used only for calls from clientName to
+// serverName, e.g. to make a single link a slow WAN link while the rest of the
+// network stays reliable.
+func (n *Network) SetLinkModel(clientName, serverName string, model FaultModel) {
+	n.faultModel.Set(clientName, serverName, model)
+}
+
+// SetReliable is a convenience for SetFaultModel(Reliable{}) or a default Lossy model.
+func (n *Network) SetReliable(reliable bool) {
+	if reliable {
+		n.SetFaultModel(Reliable{})
+		return
+	}
+	n.SetFaultModel(Lossy{DropProb: 0.1, Latency: 27 * time.Millisecond})
 }
 
 // EnableClient enables a client with specified name.
@@ -69,12 +120,78 @@ func (n *Network) DisableClient(name string) {
 
 func (n *Network) setClientEnable(name string, enabled bool) {
 	n.lock.Lock()
-	defer n.lock.Unlock()
 	if _, exist := n.clients[name]; !exist {
 		// Do nothing if client not exist.
+		n.lock.Unlock()
 		return
 	}
 	n.enabled[name] = enabled
+	n.lock.Unlock()
+
+	if !enabled {
+		n.events.publish(Event{Type: ClientDisabled, Client: name, Time: n.getClock().Now()})
+	}
+}
+
+// Partition splits the network into the given groups: a client/server can only
+// reach others in the same group. Names not mentioned in any group are left
+// unpartitioned and can still reach everyone, so pass every relevant name.
+func (n *Network) Partition(groups ...[]string) {
+	n.lock.Lock()
+	partitionGroup := make(map[string]int)
+	for i, group := range groups {
+		for _, name := range group {
+			partitionGroup[name] = i
+		}
+	}
+	n.partitionGroup = partitionGroup
+	n.lock.Unlock()
+
+	n.events.publish(Event{Type: PartitionChanged, Time: n.getClock().Now()})
+}
+
+// Heal removes any partition in effect, so every client can reach every server again.
+func (n *Network) Heal() {
+	n.lock.Lock()
+	n.partitionGroup = nil
+	n.lock.Unlock()
+
+	n.events.publish(Event{Type: PartitionChanged, Time: n.getClock().Now()})
+}
+
+// Isolate cuts the named client/server off from every other known client/server,
+// while leaving the rest of the network fully connected among themselves.
+func (n *Network) Isolate(name string) {
+	n.lock.Lock()
+	rest := make([]string, 0, len(n.clients)+len(n.servers))
+	for clientName := range n.clients {
+		if clientName != name {
+			rest = append(rest, clientName)
+		}
+	}
+	for serverName := range n.servers {
+		if serverName != name {
+			rest = append(rest, serverName)
+		}
+	}
+	n.lock.Unlock()
+
+	n.Partition([]string{name}, rest)
+}
+
+// canReach reports whether a client can reach a server under the current partition.
+func (n *Network) canReach(clientName, serverName string) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.partitionGroup == nil {
+		return true
+	}
+	clientGroup, clientOk := n.partitionGroup[clientName]
+	serverGroup, serverOk := n.partitionGroup[serverName]
+	if !clientOk || !serverOk {
+		return true
+	}
+	return clientGroup == serverGroup
 }
 
 // NewServer creates a Server with generated name.
@@ -91,8 +208,10 @@ func (n *Network) NewServer() *Server {
 
 func (n *Network) RemoveServer(server *Server) {
 	n.lock.Lock()
-	defer n.lock.Unlock()
 	delete(n.servers, server.name)
+	n.lock.Unlock()
+
+	n.events.publish(Event{Type: ServerRemoved, Server: server.name, Time: n.getClock().Now()})
 }
 
 // Bind the network address to a server. If the address is already occupied, no nothing.
@@ -142,7 +261,7 @@ func (n *Network) createClient(server *Server) *Client {
 	return client
 }
 
-func (n *Network) call(clientName, serviceMethod string, args interface{}, reply interface{}) error {
+func (n *Network) call(ctx context.Context, clientName, serviceMethod string, args interface{}, reply interface{}) error {
 	service, method, err := parseServiceMethod(serviceMethod)
 	if err != nil {
 		return err
@@ -152,7 +271,7 @@ func (n *Network) call(clientName, serviceMethod string, args interface{}, reply
 		// This is odd.
 		return fmt.Errorf("rpc: client doesn't connect to any server")
 	}
-	return n.dispatch(serverName, clientName, service, method, args, reply)
+	return n.dispatch(ctx, serverName, clientName, service, method, args, reply)
 }
 
 func (n *Network) closeClient(name string) error {
@@ -168,53 +287,106 @@ func (n *Network) closeClient(name string) error {
 }
 
 func (n *Network) dispatch(
+	ctx context.Context,
 	serverName, clientName string,
 	service, method string,
 	args, reply interface{}) error {
+	serviceMethod := service + "." + method
+	// Fetched once and reused for the whole call, so a concurrent SetClock
+	// can't mix real and virtual time within a single dispatch.
+	clock := n.getClock()
+	start := clock.Now()
+
 	server, err := n.getServer(serverName)
-	enabled, reliable, longDelay, longRecorder := n.networkCondition(clientName)
-
-	if !enabled || err != nil {
-		// Client is disabled or server is removed, treated as no reply and eventual timeout.
-		timeout := 0
-		if longDelay {
-			timeout = rand.Int() % longTimeout
-		} else {
-			timeout = rand.Int() % shortTimeout
+	enabled := n.clientEnabled(clientName)
+	reachable := n.canReach(clientName, serverName)
+
+	n.recordCall(clientName, serverName)
+	n.events.publish(Event{Type: CallStart, Client: clientName, Server: serverName, ServiceMethod: serviceMethod, Time: start})
+
+	if !enabled || err != nil || !reachable {
+		// Client is disabled, server is removed or the two are partitioned apart,
+		// treated as no reply and eventual timeout.
+		if err := n.sleep(ctx, clock, n.faultModel.DisabledTimeout(clientName, serverName)); err != nil {
+			return err
 		}
-		time.Sleep(time.Duration(timeout) * time.Millisecond)
+		n.recordTimeout(clientName, serverName)
+		n.events.publish(Event{Type: CallDropped, Client: clientName, Server: serverName, ServiceMethod: serviceMethod,
+			Latency: clock.Now().Sub(start), Err: timeoutErr, Time: clock.Now()})
 		return timeoutErr
 	}
 
-	if !reliable {
-		if msgLost() {
-			// Drop the request and return as timeout.
-			return timeoutErr
-		}
-		// Simulate a short delay
-		time.Sleep(time.Duration(rand.Int()%shortDelay) * time.Millisecond)
+	if n.faultModel.ShouldDropRequest(clientName, serverName) {
+		// Drop the request and return as timeout.
+		n.recordDrop(clientName, serverName)
+		n.events.publish(Event{Type: CallDropped, Client: clientName, Server: serverName, ServiceMethod: serviceMethod,
+			Latency: clock.Now().Sub(start), Err: timeoutErr, Time: clock.Now()})
+		return timeoutErr
+	}
+	if err := n.sleep(ctx, clock, n.faultModel.RequestDelay(clientName, serverName)); err != nil {
+		return err
 	}
 
-	err = server.dispatch(service, method, args, reply)
+	err = n.dispatchViaWire(ctx, server, service, method, args, reply)
 
-	if !reliable && msgLost() {
+	if n.faultModel.ShouldDropRequest(clientName, serverName) {
 		// Drop thr response and return as timeout.
+		n.recordDrop(clientName, serverName)
+		n.events.publish(Event{Type: CallDropped, Client: clientName, Server: serverName, ServiceMethod: serviceMethod,
+			Latency: clock.Now().Sub(start), Err: timeoutErr, Time: clock.Now()})
 		return timeoutErr
 	}
-	if longRecorder {
-		time.Sleep(time.Duration(responseDelay()) * time.Millisecond)
+	if err := n.sleep(ctx, clock, n.faultModel.ResponseDelay(clientName, serverName)); err != nil {
+		return err
 	}
 
+	n.events.publish(Event{Type: CallDelivered, Client: clientName, Server: serverName, ServiceMethod: serviceMethod,
+		Latency: clock.Now().Sub(start), Err: err, Time: clock.Now()})
 	return err
 }
 
-func (n *Network) networkCondition(clientName string) (bool, bool, bool, bool) {
+// dispatchViaWire round-trips args and reply through gob encoding before and
+// after invoking the server, just like a real RPC wire would. This is what
+// catches a test that only passes because the handler and its caller share a
+// pointer.
+func (n *Network) dispatchViaWire(ctx context.Context, server *Server, service, method string, args, reply interface{}) error {
+	argType, replyType, err := server.argTypes(service, method)
+	if err != nil {
+		return err
+	}
+
+	argsCopy := reflect.New(argType).Interface()
+	if err := roundTrip(args, argsCopy); err != nil {
+		return err
+	}
+	replyCopy := reflect.New(replyType).Interface()
+
+	if err := server.dispatch(ctx, service, method, argsCopy, replyCopy); err != nil {
+		return err
+	}
+	return roundTrip(replyCopy, reply)
+}
+
+// sleep waits for d to elapse on clock, returning early with ctx.Err() if ctx
+// is cancelled or its deadline fires first.
+func (n *Network) sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	select {
+	case <-clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (n *Network) clientEnabled(clientName string) bool {
 	n.lock.Lock()
 	defer n.lock.Unlock()
-	return n.enabled[clientName], n.reliable, n.longDelay, n.longReorder
+	return n.enabled[clientName]
 }
 
 func (n *Network) getServer(name string) (*Server, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
 	server, exist := n.servers[name]
 	if !exist {
 		return nil, errors.New("rpc: server missed")
@@ -237,12 +409,3 @@ func parseServiceMethod(serviceMethod string) (string, string, error) {
 	}
 	return tags[0], tags[1], nil
 }
-
-// The probability of request lost is 1/10.
-func msgLost() bool {
-	return rand.Int()%1000 < 100
-}
-
-func responseDelay() int {
-	return 200 + rand.Intn(1+rand.Intn(2000))
-}
@@ -0,0 +1,66 @@
+package trpc
+
+import "testing"
+
+// TestIsolatePartitionsTraffic checks that Isolate cuts the named server off
+// from every other client/server while leaving the rest of the network fully
+// connected, and that Heal restores reachability for everyone.
+func TestIsolatePartitionsTraffic(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+
+	c0, _ := dialServer(t, n, echoService{})
+	c1, s1 := dialServer(t, n, echoService{})
+
+	call := func(c *Client) error {
+		var reply echoReply
+		return c.Call("echoService.Echo", &echoArgs{N: 1}, &reply)
+	}
+
+	n.Isolate(s1.name)
+
+	if err := call(c0); err != nil {
+		t.Fatalf("c0 -> s0 should still be reachable after isolating s1, got %v", err)
+	}
+	if err := call(c1); err != timeoutErr {
+		t.Fatalf("c1 -> s1 should time out while s1 is isolated, got %v", err)
+	}
+
+	n.Heal()
+
+	if err := call(c1); err != nil {
+		t.Fatalf("c1 -> s1 should be reachable again after Heal, got %v", err)
+	}
+}
+
+// TestPartitionGroups checks that Partition only allows reachability within
+// the same group, while names left out of every group can still reach anyone.
+func TestPartitionGroups(t *testing.T) {
+	n := NewNetwork()
+	n.SetFaultModel(Reliable{})
+
+	c0, s0 := dialServer(t, n, echoService{})
+	c1, s1 := dialServer(t, n, echoService{})
+	c2, _ := dialServer(t, n, echoService{})
+
+	// Group c0/s0 together, c1/s1 together, and leave c2/s2 unpartitioned.
+	n.Partition([]string{c0.name, s0.name}, []string{c1.name, s1.name})
+
+	call := func(c *Client) error {
+		var reply echoReply
+		return c.Call("echoService.Echo", &echoArgs{N: 1}, &reply)
+	}
+
+	if err := call(c0); err != nil {
+		t.Fatalf("c0 -> s0 (same group) should be reachable, got %v", err)
+	}
+	if err := call(c1); err != nil {
+		t.Fatalf("c1 -> s1 (same group) should be reachable, got %v", err)
+	}
+	if err := call(c2); err != nil {
+		t.Fatalf("c2 -> s2 (not in any group) should be reachable, got %v", err)
+	}
+	if n.canReach(c0.name, s1.name) {
+		t.Fatalf("c0 -> s1 (different groups) should not be reachable")
+	}
+}
@@ -1,6 +1,7 @@
 package trpc
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"reflect"
@@ -17,13 +18,33 @@ type service struct {
 	methods  map[string]reflect.Method // registered methods
 }
 
-func (s *service) dispatch(method string, args, reply interface{}) error {
+func (s *service) dispatch(ctx context.Context, method string, args, reply interface{}) error {
 	m, err := s.getMethod(method)
 	if err != nil {
 		return err
 	}
-	m.Func.Call([]reflect.Value{s.receiver, reflect.ValueOf(args), reflect.ValueOf(reply)})
-	return nil
+
+	// Run the handler in its own goroutine so it can be abandoned, rather than
+	// waited on, once ctx's deadline fires. recover() keeps a handler panic
+	// from crashing the process out of this now-detached goroutine; it's
+	// reported back as an error instead, same as any other handler failure.
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("rpc: method %s panicked: %v", method, r)
+			}
+		}()
+		m.Func.Call([]reflect.Value{s.receiver, reflect.ValueOf(args), reflect.ValueOf(reply)})
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 func (s *service) addMethods(methods []reflect.Method) {
@@ -44,6 +65,16 @@ func (s *service) getMethod(name string) (reflect.Method, error) {
 	return m, nil
 }
 
+// argTypes returns the concrete arg and reply types expected by method, so the
+// caller can allocate fresh values to gob-decode into.
+func (s *service) argTypes(method string) (argType, replyType reflect.Type, err error) {
+	m, err := s.getMethod(method)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.Type.In(1).Elem(), m.Type.In(2).Elem(), nil
+}
+
 // Server is an alternative of native Server in net/rpc for test. You can simulate
 // request/response lost, messages delay and network partition with this server.
 // NOTE: There's no actual network connections established if you use this server.
@@ -121,12 +152,22 @@ func (server *Server) getService(name string) (*service, error) {
 	return s, nil
 }
 
-func (server *Server) dispatch(service, method string, args, reply interface{}) error {
+func (server *Server) dispatch(ctx context.Context, service, method string, args, reply interface{}) error {
 	srv, err := server.getService(service)
 	if err != nil {
 		return nil
 	}
-	return srv.dispatch(method, args, reply)
+	return srv.dispatch(ctx, method, args, reply)
+}
+
+// argTypes returns the concrete arg and reply types service.method expects, so
+// the caller can gob-decode into a fresh value of the right type.
+func (server *Server) argTypes(service, method string) (argType, replyType reflect.Type, err error) {
+	srv, err := server.getService(service)
+	if err != nil {
+		return nil, nil, err
+	}
+	return srv.argTypes(method)
 }
 
 func validMethodType(typ reflect.Type) bool {